@@ -0,0 +1,61 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package errorex
+
+import "fmt"
+
+// GetData returns err's own data payload, type asserted to T, avoiding
+// the explicit .(T) assertion Data() requires. It does not walk the
+// wrap chain; see FindData for that. ok is false if err is not an
+// *ErrorEx or its data is not assignable to T.
+func GetData[T any](err error) (data T, ok bool) {
+	eex, isEE := err.(*ErrorEx)
+	if !isEE {
+		return
+	}
+	data, ok = eex.data.(T)
+	return
+}
+
+// FindData walks the complete error tree rooted at err - its wrap
+// chain and any extras - in pre-order DFS and returns the first data
+// payload assignable to T. Errors not of ErrorEx type are skipped but
+// still traversed if they expose Unwrap() error or Unwrap() []error.
+func FindData[T any](err error) (data T, ok bool) {
+	if err == nil {
+		return
+	}
+	if data, ok = GetData[T](err); ok {
+		return
+	}
+	for _, child := range children(err) {
+		if data, ok = FindData[T](child); ok {
+			return
+		}
+	}
+	return
+}
+
+// WithData wraps err, attaching data as its payload, without requiring
+// a derived wrap message the way WrapData does. If err is nil,
+// WithData returns nil.
+func WithData(err error, data interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return &ErrorEx{err: err, data: data, stack: callers(3)}
+}
+
+// WithDataf is like WithData but also formats a wrap message from
+// format and args, mirroring fmt.Errorf's ergonomics. As with Wrap and
+// WrapData, err's own message is only surfaced by Error() when err is
+// itself an *ErrorEx; the message built here is just this node's own
+// text, not a pre-baked concatenation with err.Error().
+func WithDataf(err error, data interface{}, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return &ErrorEx{err: err, txt: fmt.Sprintf(format, args...), data: data, stack: callers(3)}
+}
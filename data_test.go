@@ -0,0 +1,74 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package errorex
+
+import "testing"
+
+func TestGetData(t *testing.T) {
+	err := New("base").WrapData("error", "payload")
+
+	if data, ok := GetData[string](err); !ok || data != "payload" {
+		t.Fatal()
+	}
+	if _, ok := GetData[int](err); ok {
+		t.Fatal("expected no int payload")
+	}
+	if _, ok := GetData[string](err.Unwrap()[0]); ok {
+		t.Fatal("GetData should not walk the chain")
+	}
+}
+
+func TestFindData(t *testing.T) {
+	err := New("base").WrapData("error", 42).Wrap("outer")
+
+	if data, ok := FindData[int](err); !ok || data != 42 {
+		t.Fatal()
+	}
+	if _, ok := FindData[string](err); ok {
+		t.Fatal("expected no string payload in chain")
+	}
+
+	joined := Join(New("a"), New("b").WrapData("c", "found"))
+	if data, ok := FindData[string](joined); !ok || data != "found" {
+		t.Fatal("FindData should walk extras")
+	}
+}
+
+func TestWithData(t *testing.T) {
+	if WithData(nil, "payload") != nil {
+		t.Fatal("WithData(nil, ...) should return nil")
+	}
+
+	err := WithData(ErrTest, "payload")
+	if err.Error() != "test" {
+		t.Fatal(err.Error())
+	}
+	if data, ok := GetData[string](err); !ok || data != "payload" {
+		t.Fatal()
+	}
+}
+
+func TestWithDataf(t *testing.T) {
+	if WithDataf(nil, 1, "msg") != nil {
+		t.Fatal("WithDataf(nil, ...) should return nil")
+	}
+
+	// err is not an *ErrorEx, so - like Wrap and WrapData - its own
+	// message is not surfaced; only this node's own formatted text is.
+	err := WithDataf(ErrTest, 7, "failed after %d retries", 3)
+	if err.Error() != "failed after 3 retries" {
+		t.Fatal(err.Error())
+	}
+	if data, ok := GetData[int](err); !ok || data != 7 {
+		t.Fatal()
+	}
+
+	// err is an *ErrorEx, so its message is picked up via the normal
+	// wrap chain and must not be duplicated into the new node's text.
+	wrapped := WithDataf(New("base"), 42, "extra info %d", 1)
+	if wrapped.Error() != "base: extra info 1" {
+		t.Fatal(wrapped.Error())
+	}
+}
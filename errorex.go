@@ -27,12 +27,22 @@ type ErrorEx struct {
 	data interface{}
 	// Extra are extra errors carried with this error.
 	extra []error
+	// stack are the program counters captured at construction, if
+	// stack capture was enabled at the time.
+	stack []uintptr
+	// key is this error's registered sentinel identity, set by
+	// Register. Empty unless this error was registered.
+	key string
+	// formatter overrides the package-level default formatter for
+	// this error specifically, set by WithFormatter.
+	formatter Formatter
 }
 
 // New returns a new ErrorEx and sets its' message.
 func New(message string) *ErrorEx {
 	return &ErrorEx{
-		txt: message,
+		txt:   message,
+		stack: callers(3),
 	}
 }
 
@@ -40,10 +50,16 @@ func New(message string) *ErrorEx {
 // which will be used as a format string for errors deriving from it.
 // Resulting error is used as a placeholder and will be skipped when
 // printing but remains in the error chain and responds to Is() and As().
+//
+// NewFormat builds its own ErrorEx rather than delegating to New so
+// that the captured stack trace still starts at NewFormat's caller
+// instead of at New.
 func NewFormat(format string) (err *ErrorEx) {
-	err = New(format)
-	err.fmt = true
-	return
+	return &ErrorEx{
+		txt:   format,
+		fmt:   true,
+		stack: callers(3),
+	}
 }
 
 // extrastring returns preformated extra error messages as a string.
@@ -56,105 +72,13 @@ func (ee *ErrorEx) extrastring() (message string) {
 	return
 }
 
-// Error implements the error interface.
-//
-// It uses a custom printing scheme:
-//
-// First error in the chain is always separated with a ':' from derived error
-// messages.
-// Wrapped errors are separated with a ';' if there are more than 3 wrap levels
-// and the error is between 3rd and last level.
-// Last error in the wrap stack is always separated with a '>' unless it
-// directly wraps the base error in which case it is separated by ':'.
-//
-// Example:
-//  New("base").Wrap("sub1").Error()
-//  Output: base: sub1
-//
-// Example:
-//  New("base").Wrap("sub1").Wrap("sub2").Error()
-//  Output: base: sub1 > sub2
-//
-// Example:
-//  New("base").Wrap("sub1").Wrap("sub2").Wrap("sub3").Error()
-//  Output: base: sub1; sub2 > sub3
-//
-// Example:
-//  New("base").Wrap("sub1").Wrap("sub2").Wrap("sub3").Wrap("sub4").Error()
-//  Output: base: sub1; sub2; sub3 > sub4
-//
-// Cause errors format the same way and are appended to final error after a '<'
-// prefix.
-//
-// Example:
-//  New("base").WrapCause("error", New("cause"))
-//  Output: base: error < cause
-//
-// Extra errors carried by an error are appended and separated by ' + '
-//
-// Example:
-//  New("base").Wrap("sub").Extra(New("extra"))
-//  Output: base: sub + extra
-//
-// Errors created with NewFormat and WrapFormat are format placeholder errors
-// and are not printed when printing the wrap chain.
-//
-// Errors with an empty message are skipped when printing, regardless if they
-// carry causes or extra errors.
-func (ee *ErrorEx) Error() (message string) {
-
-	// Set base message.
-	if ee.txt == "" {
-		if ee.err != nil {
-			message = ee.err.Error()
-		}
-	}
-	if !ee.fmt {
-		message = ee.txt
-	}
-	if ee.cause != nil {
-		message = fmt.Sprintf("%s < %v", message, ee.cause)
-	}
-
-	// Build wrap stack.
-	stack := []string{}
-	for eex, ok := (ee.err).(*ErrorEx); ok; eex, ok = (eex.err).(*ErrorEx) {
-		if eex.fmt || len(eex.txt) == 0 {
-			continue
-		}
-		stack = append(stack, eex.txt+eex.extrastring())
-		if cause := eex.Cause(); cause != nil {
-			stack[len(stack)-1] += fmt.Sprintf(" < %s", cause.Error())
-		}
-	}
-
-	// Format stack.
-	if len(stack) > 0 {
-		if len(stack) == 1 {
-			if message == "" {
-				message = stack[0]
-			} else {
-				message = fmt.Sprintf("%s: %s", stack[0], message)
-			}
-		} else {
-			msg := fmt.Sprintf("%s:", stack[len(stack)-1])
-			stack = stack[:len(stack)-1]
-			for len(stack) > 0 {
-				if len(stack) == 1 {
-					msg = fmt.Sprintf("%s %s", msg, stack[len(stack)-1])
-				} else {
-					msg = fmt.Sprintf("%s %s;", msg, stack[len(stack)-1])
-				}
-				stack = stack[:len(stack)-1]
-			}
-			message = fmt.Sprintf("%s > %s", msg, message)
-		}
-	}
-
-	// Append extra.
-	message += ee.extrastring()
-
-	return
+// Error implements the error interface by rendering ee with its
+// formatter - ee.WithFormatter's value if set, otherwise the
+// package-level default set by SetFormatter, which is CompactFormatter
+// unless changed. See CompactFormatter for the default printing scheme
+// and VerboseFormatter for a multiline alternative.
+func (ee *ErrorEx) Error() string {
+	return ee.activeFormatter().Format(ee)
 }
 
 // is is the implementation of Is.
@@ -175,15 +99,10 @@ func (ee *ErrorEx) Is(target error) bool {
 	return ee.is(target, ee.cause)
 }
 
-// Unwrap implements error.Unwrap().
-func (ee *ErrorEx) Unwrap() error {
-	return ee.err
-}
-
 // Wrap wraps this error with a new error, sets new error message,
 // then returns it.
 func (ee *ErrorEx) Wrap(message string) *ErrorEx {
-	return &ErrorEx{err: ee, txt: message}
+	return &ErrorEx{err: ee, txt: message, stack: callers(3)}
 }
 
 // WrapFormat wraps this error with a new non-printable error whose
@@ -194,10 +113,12 @@ func (ee *ErrorEx) Wrap(message string) *ErrorEx {
 //
 // The resulting error is skipped when printing the error chain but
 // remains in the error chain and responds to Is() and As() properly.
+//
+// WrapFormat builds its own ErrorEx rather than delegating to Wrap so
+// that the captured stack trace still starts at WrapFormat's caller
+// instead of at Wrap.
 func (ee *ErrorEx) WrapFormat(format string) (err *ErrorEx) {
-	err = ee.Wrap(format)
-	err.fmt = true
-	return
+	return &ErrorEx{err: ee, txt: format, fmt: true, stack: callers(3)}
 }
 
 // autoformat returns a formatted error message using this error message
@@ -214,8 +135,12 @@ func (ee *ErrorEx) autoformat(args ...interface{}) string {
 // specified args and this error message as a format string.
 // WrapArgs should be used on errors which were constructed using
 // NewFormat or WrapFormat using a format string as error message.
+//
+// WrapArgs builds its own ErrorEx rather than delegating to Wrap so
+// that the captured stack trace still starts at WrapArgs' caller
+// instead of at Wrap.
 func (ee *ErrorEx) WrapArgs(args ...interface{}) *ErrorEx {
-	return ee.Wrap(ee.autoformat(args...))
+	return &ErrorEx{err: ee, txt: ee.autoformat(args...), stack: callers(3)}
 }
 
 // WrapCause returns a new derived ErrorEx that wraps a cause error.
@@ -230,14 +155,14 @@ func (ee *ErrorEx) WrapArgs(args ...interface{}) *ErrorEx {
 // Derived ErrorEx unwraps to this error.
 // Wrapped cause error is retrievable with Cause().
 func (ee *ErrorEx) WrapCause(message string, err error) *ErrorEx {
-	return &ErrorEx{cause: err, err: ee, txt: message}
+	return &ErrorEx{cause: err, err: ee, txt: message, stack: callers(3)}
 }
 
 // WrapCauseArgs derives a new error which wraps a cause error and formats
 // its error message from specified args and this error message as a format
 // string. See WrapCause for more details.
 func (ee *ErrorEx) WrapCauseArgs(err error, args ...interface{}) *ErrorEx {
-	return &ErrorEx{cause: err, err: ee, txt: ee.autoformat(args...)}
+	return &ErrorEx{cause: err, err: ee, txt: ee.autoformat(args...), stack: callers(3)}
 }
 
 // Cause returns the error that caused this error, which could be nil.
@@ -247,21 +172,23 @@ func (ee *ErrorEx) Cause() error {
 
 // WrapData returns a new derived ErrorEx that wraps custom data.
 func (ee *ErrorEx) WrapData(message string, data interface{}) *ErrorEx {
-	return &ErrorEx{data: data, err: ee, txt: message}
+	return &ErrorEx{data: data, err: ee, txt: message, stack: callers(3)}
 }
 
 // WrapDataFormat wraps an error like WrapFormat but attatches data to it.
+//
+// WrapDataFormat builds its own ErrorEx rather than delegating to
+// WrapFormat so that the captured stack trace still starts at
+// WrapDataFormat's caller instead of at WrapFormat.
 func (ee *ErrorEx) WrapDataFormat(format string, data interface{}) *ErrorEx {
-	err := ee.WrapFormat(format)
-	err.data = data
-	return err
+	return &ErrorEx{err: ee, txt: format, fmt: true, data: data, stack: callers(3)}
 }
 
 // WrapDataArgs derives a new error which wraps custom data and formats
 // its error message from specified args and this error message as a format
 // string. See WrapData for more details.
 func (ee *ErrorEx) WrapDataArgs(data interface{}, args ...interface{}) *ErrorEx {
-	return &ErrorEx{data: data, err: ee, txt: ee.autoformat(args...)}
+	return &ErrorEx{data: data, err: ee, txt: ee.autoformat(args...), stack: callers(3)}
 }
 
 // Data returns this error data, which could be nil.
@@ -269,21 +196,19 @@ func (ee *ErrorEx) Data() (data interface{}) {
 	return ee.data
 }
 
-// AnyData returns first set data down the complete error wrap chain starting from
-// this error. Errors not of ErrorEx type are skipped. If no set data is found
-// result will be nil.
+// AnyData returns first set data down the complete error tree starting from
+// this error, including its wrap chain and any extras. Errors not of
+// ErrorEx type are skipped. If no set data is found result will be nil.
 func (ee *ErrorEx) AnyData() (data interface{}) {
-	for err := error(ee); ; {
-		if err == nil {
-			break
-		}
-		if eex, ok := err.(*ErrorEx); ok {
-			data = eex.Data()
-			if data != nil {
-				break
+	if eex, ok := error(ee).(*ErrorEx); ok && eex.data != nil {
+		return eex.data
+	}
+	for _, child := range ee.Unwrap() {
+		if eex, ok := child.(*ErrorEx); ok {
+			if data = eex.AnyData(); data != nil {
+				return
 			}
 		}
-		err = errors.Unwrap(err)
 	}
 	return
 }
@@ -121,7 +121,7 @@ func TestExtra(t *testing.T) {
 func TestUnwrap(t *testing.T) {
 	base := New("base")
 	wrap := base.Wrap("wrap")
-	if wrap.Unwrap() != base {
+	if unwrapped := wrap.Unwrap(); len(unwrapped) != 1 || unwrapped[0] != error(base) {
 		t.Fatal()
 	}
 }
@@ -140,3 +140,32 @@ func TestIs(t *testing.T) {
 		t.Fatal()
 	}
 }
+
+func TestIsExtra(t *testing.T) {
+	extra := New("extra")
+	err := New("base").Extra(extra)
+	if !errors.Is(err, extra) {
+		t.Fatal("errors.Is should see extras via multi-unwrap")
+	}
+}
+
+func TestJoin(t *testing.T) {
+	if Join(nil, nil) != nil {
+		t.Fatal("Join of only nils should return nil")
+	}
+
+	err1 := New("err1")
+	err2 := New("err2")
+	joined := Join(nil, err1, err2)
+	if joined.Error() != "err1 + err2" {
+		t.Fatal(joined.Error())
+	}
+	if !errors.Is(joined, err1) || !errors.Is(joined, err2) {
+		t.Fatal("errors.Is should see every joined error")
+	}
+
+	wrapped := New("base").Wrap("wrap").WrapCause("error", joined)
+	if !errors.Is(wrapped, err1) || !errors.Is(wrapped, err2) {
+		t.Fatal("errors.Is should traverse a joined error reached as a cause")
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package errorex
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Formatter renders an *ErrorEx to its error message. Error() uses the
+// formatter set on the error itself via WithFormatter, falling back to
+// the package-level default set by SetFormatter.
+type Formatter interface {
+	Format(ee *ErrorEx) string
+}
+
+// defaultFormatter is the package-level fallback used by Error() for
+// errors that have not been given their own formatter.
+var defaultFormatter Formatter = CompactFormatter{}
+
+// SetFormatter changes the package-level default formatter used by
+// Error() for errors that have not been given their own formatter via
+// WithFormatter.
+func SetFormatter(f Formatter) {
+	defaultFormatter = f
+}
+
+// WithFormatter sets the formatter used by this error's Error() method,
+// overriding the package-level default, and returns self.
+func (ee *ErrorEx) WithFormatter(f Formatter) *ErrorEx {
+	ee.formatter = f
+	return ee
+}
+
+// activeFormatter returns the formatter set on ee via WithFormatter, or
+// the package-level default from SetFormatter if none was set. Error()
+// and the %+v fmt.Formatter implementation both render through it, so
+// a formatter change applies consistently regardless of how the error
+// is printed.
+func (ee *ErrorEx) activeFormatter() Formatter {
+	if ee.formatter != nil {
+		return ee.formatter
+	}
+	return defaultFormatter
+}
+
+// CompactFormatter is the package's original printing scheme:
+//
+// First error in the chain is always separated with a ':' from derived error
+// messages.
+// Wrapped errors are separated with a ';' if there are more than 3 wrap levels
+// and the error is between 3rd and last level.
+// Last error in the wrap stack is always separated with a '>' unless it
+// directly wraps the base error in which case it is separated by ':'.
+//
+// Example:
+//  New("base").Wrap("sub1").Error()
+//  Output: base: sub1
+//
+// Example:
+//  New("base").Wrap("sub1").Wrap("sub2").Error()
+//  Output: base: sub1 > sub2
+//
+// Example:
+//  New("base").Wrap("sub1").Wrap("sub2").Wrap("sub3").Error()
+//  Output: base: sub1; sub2 > sub3
+//
+// Example:
+//  New("base").Wrap("sub1").Wrap("sub2").Wrap("sub3").Wrap("sub4").Error()
+//  Output: base: sub1; sub2; sub3 > sub4
+//
+// Cause errors format the same way and are appended to final error after a '<'
+// prefix.
+//
+// Example:
+//  New("base").WrapCause("error", New("cause"))
+//  Output: base: error < cause
+//
+// Extra errors carried by an error are appended and separated by ' + '
+//
+// Example:
+//  New("base").Wrap("sub").Extra(New("extra"))
+//  Output: base: sub + extra
+//
+// Errors created with NewFormat and WrapFormat are format placeholder errors
+// and are not printed when printing the wrap chain.
+//
+// Errors with an empty message are skipped when printing, regardless if they
+// carry causes or extra errors.
+type CompactFormatter struct{}
+
+// Format implements Formatter.
+func (CompactFormatter) Format(ee *ErrorEx) (message string) {
+
+	// Set base message. An *ErrorEx parent is rendered by the wrap stack
+	// below, so only a foreign parent's message is used here.
+	if ee.txt == "" {
+		if _, isEE := ee.err.(*ErrorEx); !isEE && ee.err != nil {
+			message = ee.err.Error()
+		}
+	} else if !ee.fmt {
+		message = ee.txt
+	}
+	if ee.cause != nil {
+		message = fmt.Sprintf("%s < %v", message, ee.cause)
+	}
+
+	// Build wrap stack.
+	stack := []string{}
+	for eex, ok := (ee.err).(*ErrorEx); ok; eex, ok = (eex.err).(*ErrorEx) {
+		if eex.fmt || len(eex.txt) == 0 {
+			continue
+		}
+		stack = append(stack, eex.txt+eex.extrastring())
+		if cause := eex.Cause(); cause != nil {
+			stack[len(stack)-1] += fmt.Sprintf(" < %s", cause.Error())
+		}
+	}
+
+	// Format stack.
+	if len(stack) > 0 {
+		if len(stack) == 1 {
+			if message == "" {
+				message = stack[0]
+			} else {
+				message = fmt.Sprintf("%s: %s", stack[0], message)
+			}
+		} else {
+			msg := fmt.Sprintf("%s:", stack[len(stack)-1])
+			stack = stack[:len(stack)-1]
+			for len(stack) > 0 {
+				if len(stack) == 1 {
+					msg = fmt.Sprintf("%s %s", msg, stack[len(stack)-1])
+				} else {
+					msg = fmt.Sprintf("%s %s;", msg, stack[len(stack)-1])
+				}
+				stack = stack[:len(stack)-1]
+			}
+			message = fmt.Sprintf("%s > %s", msg, message)
+		}
+	}
+
+	// Append extra.
+	message += ee.extrastring()
+	// An empty base message, as produced by Join, leaves a dangling
+	// separator in front of the first extra.
+	message = strings.TrimPrefix(message, " + ")
+
+	return
+}
+
+// VerboseFormatter renders an error tree one frame per line, indented
+// like a Java or Python traceback: the wrap chain from base to
+// outermost error, each interleaved with its captured stack frames (see
+// StackTrace), followed by a "Caused by:" section for the cause and an
+// "Extra:" line per extra error.
+type VerboseFormatter struct{}
+
+// Format implements Formatter.
+func (VerboseFormatter) Format(ee *ErrorEx) string {
+	var chain []*ErrorEx
+	for eex, ok := ee, true; ok; eex, ok = eex.err.(*ErrorEx) {
+		if !eex.fmt && eex.txt != "" {
+			chain = append(chain, eex)
+		}
+	}
+
+	var b strings.Builder
+	for i := len(chain) - 1; i >= 0; i-- {
+		eex := chain[i]
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(eex.txt)
+		for _, frame := range eex.StackTrace() {
+			fmt.Fprintf(&b, "\n\t%s\n\t\t%s:%d", frame.Function, frame.File, frame.Line)
+		}
+	}
+	if ee.cause != nil {
+		fmt.Fprintf(&b, "\nCaused by: %v", ee.cause)
+	}
+	for _, extra := range ee.extra {
+		fmt.Fprintf(&b, "\nExtra: %v", extra)
+	}
+	return b.String()
+}
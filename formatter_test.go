@@ -0,0 +1,41 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package errorex
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithFormatter(t *testing.T) {
+	err := New("base").Wrap("sub").WithFormatter(VerboseFormatter{})
+	if err.Error() == (CompactFormatter{}).Format(err) {
+		t.Fatal("expected WithFormatter to override the compact rendering")
+	}
+}
+
+func TestSetFormatter(t *testing.T) {
+	SetFormatter(VerboseFormatter{})
+	defer SetFormatter(CompactFormatter{})
+
+	err := New("base").Wrap("sub")
+	if !strings.Contains(err.Error(), "base") || !strings.Contains(err.Error(), "sub") {
+		t.Fatal("expected both messages in verbose output")
+	}
+}
+
+func TestVerboseFormatter(t *testing.T) {
+	err := New("base").Wrap("sub").WrapCause("error", New("cause")).Extra(New("extra"))
+	s := VerboseFormatter{}.Format(err)
+
+	for _, want := range []string{"base", "sub", "error", "Caused by: cause", "Extra: extra"} {
+		if !strings.Contains(s, want) {
+			t.Fatalf("expected output to contain %q, got:\n%s", want, s)
+		}
+	}
+	if !strings.Contains(s, "\n\t") {
+		t.Fatal("expected stack frames to be indented on their own lines")
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package errorex
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DataExtras is implemented by errors which carry a custom data
+// payload and extra side-errors, such as *ErrorEx. MarshalTree uses it
+// to serialize arbitrary errors that are not themselves *ErrorEx.
+type DataExtras interface {
+	Data() interface{}
+	Extras() []error
+}
+
+// errorTree is the wire format produced by MarshalJSON and MarshalTree
+// and consumed by UnmarshalJSON. It mirrors the wrap/cause/extra shape
+// of ErrorEx closely enough to reconstruct an opaque but Is-comparable
+// tree on the receiving side of a JSON round-trip.
+type errorTree struct {
+	Message string       `json:"message"`
+	Format  bool         `json:"format,omitempty"`
+	Cause   *errorTree   `json:"cause,omitempty"`
+	Wraps   *errorTree   `json:"wraps,omitempty"`
+	Extras  []*errorTree `json:"extras,omitempty"`
+	// Data is round-tripped through encoding/json's untyped interface{}
+	// decoding. Numeric payloads come back as float64 regardless of
+	// their original Go type, so GetData[int]/FindData[int] (and any
+	// other non-float64 numeric T) fail on a decoded error even though
+	// they succeeded before marshaling. See TestDataJSONNumericRoundTrip.
+	Data interface{} `json:"data,omitempty"`
+	Key  string      `json:"key,omitempty"`
+	// Stack is this node's own captured call stack (see StackTrace),
+	// one "function file:line" entry per frame, deepest call first. It
+	// is informational only: UnmarshalJSON/fromTree cannot turn these
+	// strings back into the program counters StackTrace needs, so a
+	// decoded *ErrorEx always reports a nil StackTrace even when its
+	// wire form carried one. Structured log/RPC consumers that only
+	// read the JSON, rather than decoding back into an *ErrorEx, still
+	// see the frames.
+	Stack []string `json:"stack,omitempty"`
+}
+
+// buildTree converts err into its wire representation, probing for
+// Unwrap() error, Unwrap() []error, Cause() error and DataExtras on
+// errors that are not *ErrorEx, so arbitrary error trees survive the
+// round-trip alongside ErrorEx ones.
+func buildTree(err error) *errorTree {
+	if err == nil {
+		return nil
+	}
+	if eex, ok := err.(*ErrorEx); ok {
+		tree := &errorTree{
+			Message: eex.txt,
+			Format:  eex.fmt,
+			Data:    eex.data,
+			Wraps:   buildTree(eex.err),
+			Cause:   buildTree(eex.cause),
+			Key:     eex.key,
+		}
+		for _, frame := range eex.StackTrace() {
+			tree.Stack = append(tree.Stack, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+		}
+		for _, extra := range eex.extra {
+			tree.Extras = append(tree.Extras, buildTree(extra))
+		}
+		return tree
+	}
+
+	tree := &errorTree{Message: err.Error()}
+	if c, ok := err.(interface{ Cause() error }); ok {
+		tree.Cause = buildTree(c.Cause())
+	}
+	if de, ok := err.(DataExtras); ok {
+		tree.Data = de.Data()
+		for _, extra := range de.Extras() {
+			tree.Extras = append(tree.Extras, buildTree(extra))
+		}
+	}
+	if kids := children(err); len(kids) > 0 {
+		tree.Wraps = buildTree(kids[0])
+		for _, extra := range kids[1:] {
+			tree.Extras = append(tree.Extras, buildTree(extra))
+		}
+	}
+	return tree
+}
+
+// fromTree reconstructs an *ErrorEx from its wire representation. If
+// tree carries a registered key, the original sentinel *ErrorEx is
+// returned so errors.Is against it still succeeds after a round-trip.
+// Otherwise the result is opaque - it carries the original messages,
+// data and shape so Error() and errors.Is/As against it still behave
+// sensibly - but it is a new value, not the original error.
+func fromTree(tree *errorTree) *ErrorEx {
+	if tree == nil {
+		return nil
+	}
+	if tree.Key != "" {
+		if sentinel, ok := Lookup(tree.Key); ok {
+			return sentinel
+		}
+	}
+	eex := &ErrorEx{
+		txt:  tree.Message,
+		fmt:  tree.Format,
+		data: tree.Data,
+		key:  tree.Key,
+	}
+	if tree.Wraps != nil {
+		eex.err = fromTree(tree.Wraps)
+	}
+	if tree.Cause != nil {
+		eex.cause = fromTree(tree.Cause)
+	}
+	for _, extra := range tree.Extras {
+		eex.extra = append(eex.extra, fromTree(extra))
+	}
+	return eex
+}
+
+// MarshalJSON implements json.Marshaler, encoding the full wrap chain,
+// cause and extras of ee as a nested object with "message", "format",
+// "cause", "wraps", "extras", "data", "key" and "stack" fields.
+//
+// "stack" is each node's own StackTrace, formatted as one "function
+// file:line" string per frame; see the errorTree.Stack caveat for why
+// it does not survive UnmarshalJSON back into an *ErrorEx.
+//
+// Note that a numeric Data payload round-trips as a float64 (standard
+// encoding/json behavior for interface{}), not its original Go type;
+// GetData/FindData called with a non-float64 numeric type argument
+// will not find it after UnmarshalJSON. Callers that need exact numeric
+// types preserved should convert Data to float64 themselves before
+// relying on it post round-trip, or avoid transporting numeric payloads
+// this way.
+func (ee *ErrorEx) MarshalJSON() ([]byte, error) {
+	return json.Marshal(buildTree(ee))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, reconstructing an opaque
+// but Is-comparable error tree from MarshalJSON or MarshalTree output.
+// See MarshalJSON for the numeric Data caveat.
+func (ee *ErrorEx) UnmarshalJSON(data []byte) error {
+	var tree errorTree
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return err
+	}
+	*ee = *fromTree(&tree)
+	return nil
+}
+
+// MarshalTree marshals an arbitrary error tree to JSON in the same
+// shape as ErrorEx.MarshalJSON, probing err and its descendants for
+// Unwrap() error, Unwrap() []error, Cause() error and DataExtras so
+// errors from other packages serialize alongside ErrorEx ones.
+func MarshalTree(err error) ([]byte, error) {
+	return json.Marshal(buildTree(err))
+}
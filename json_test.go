@@ -0,0 +1,115 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package errorex
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	err := New("base").Wrap("sub").WrapData("data", "payload").WrapCause("error", New("cause"))
+
+	b, jerr := json.Marshal(err)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+
+	var decoded ErrorEx
+	if jerr = json.Unmarshal(b, &decoded); jerr != nil {
+		t.Fatal(jerr)
+	}
+	if decoded.Error() != err.Error() {
+		t.Fatalf("got %q, want %q", decoded.Error(), err.Error())
+	}
+}
+
+func TestMarshalTree(t *testing.T) {
+	b, err := MarshalTree(Join(New("a"), New("b").WrapData("c", "payload")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tree errorTree
+	if err = json.Unmarshal(b, &tree); err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.Extras) != 2 {
+		t.Fatalf("expected 2 extras, got %d", len(tree.Extras))
+	}
+	if tree.Extras[1].Data != "payload" {
+		t.Fatal("expected second extra's data to survive")
+	}
+}
+
+// TestDataJSONNumericRoundTrip documents a known limitation: numeric
+// Data payloads decode as float64, not their original Go type, so a
+// GetData call for the original type fails after a JSON round-trip.
+// See the caveat on MarshalJSON.
+func TestDataJSONNumericRoundTrip(t *testing.T) {
+	err := New("wrapped").WrapData("status", 404)
+
+	b, jerr := MarshalTree(err)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+
+	var decoded ErrorEx
+	if jerr = json.Unmarshal(b, &decoded); jerr != nil {
+		t.Fatal(jerr)
+	}
+
+	if _, ok := GetData[int](&decoded); ok {
+		t.Fatal("expected int payload to not survive the JSON round-trip")
+	}
+	if data, ok := GetData[float64](&decoded); !ok || data != 404 {
+		t.Fatal("expected the payload to decode as float64")
+	}
+}
+
+// TestMarshalTreeStack documents that a node's StackTrace is encoded
+// into the wire format's "stack" field, but UnmarshalJSON/fromTree
+// cannot reconstruct program counters from it, so a decoded *ErrorEx
+// reports no StackTrace even though its JSON carried one.
+func TestMarshalTreeStack(t *testing.T) {
+	err := New("base")
+
+	b, jerr := MarshalTree(err)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+
+	var tree errorTree
+	if jerr = json.Unmarshal(b, &tree); jerr != nil {
+		t.Fatal(jerr)
+	}
+	if len(tree.Stack) == 0 {
+		t.Fatal("expected the wire format to carry captured stack frames")
+	}
+
+	var decoded ErrorEx
+	if jerr = json.Unmarshal(b, &decoded); jerr != nil {
+		t.Fatal(jerr)
+	}
+	if decoded.StackTrace() != nil {
+		t.Fatal("expected a decoded ErrorEx to have no reconstructed StackTrace")
+	}
+}
+
+func TestMarshalTreeGenericError(t *testing.T) {
+	b, err := MarshalTree(errors.New("plain"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tree errorTree
+	if err = json.Unmarshal(b, &tree); err != nil {
+		t.Fatal(err)
+	}
+	if tree.Message != "plain" {
+		t.Fatalf("got %q", tree.Message)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package errorex
+
+// children returns the immediate child errors of err, whether it
+// exposes them via the single-error Unwrap() error contract or the
+// multi-error Unwrap() []error contract introduced in Go 1.20. It is
+// used to walk arbitrary error trees, not just ErrorEx ones.
+func children(err error) []error {
+	switch x := err.(type) {
+	case interface{ Unwrap() []error }:
+		return x.Unwrap()
+	case interface{ Unwrap() error }:
+		if child := x.Unwrap(); child != nil {
+			return []error{child}
+		}
+	}
+	return nil
+}
+
+// Unwrap implements the Go 1.20 multi-error contract. It returns the
+// wrapped parent error, if any, followed by this error's extras, so
+// that errors.Is and errors.As traverse the full tree - parent first,
+// then extras, in pre-order DFS - and not just the single wrap chain.
+func (ee *ErrorEx) Unwrap() []error {
+	errs := make([]error, 0, 1+len(ee.extra))
+	if ee.err != nil {
+		errs = append(errs, ee.err)
+	}
+	return append(errs, ee.extra...)
+}
+
+// Join returns an *ErrorEx whose extras are the non-nil errs, exposed
+// via Unwrap() []error so that errors.Is and errors.As see all of
+// them. If every err is nil, Join returns nil. Unlike errors.Join the
+// result is still a fully featured ErrorEx and can be wrapped, given a
+// cause or carry its own data like any other ErrorEx.
+func Join(errs ...error) error {
+	joined := &ErrorEx{stack: callers(3)}
+	for _, err := range errs {
+		if err != nil {
+			joined.extra = append(joined.extra, err)
+		}
+	}
+	if len(joined.extra) == 0 {
+		return nil
+	}
+	return joined
+}
@@ -0,0 +1,53 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package errorex
+
+import (
+	"fmt"
+	"sync"
+)
+
+// registry maps a stable string key to the sentinel *ErrorEx it was
+// registered under, so that a round-trip through JSON can recover the
+// original pointer and remain comparable with errors.Is. Individual
+// *ErrorEx values are not safe for concurrent use, but the registry
+// itself is.
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]*ErrorEx{}
+)
+
+// Register gives err a stable string identity, recorded on err so
+// MarshalJSON/MarshalTree can emit it and UnmarshalJSON can resolve it
+// back to this same *ErrorEx. Register returns an error if key is
+// already registered to a different error; see MustRegister to panic
+// on collision instead.
+func Register(key string, err *ErrorEx) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if existing, ok := registry[key]; ok && existing != err {
+		return New(fmt.Sprintf("errorex: key %q already registered", key))
+	}
+	registry[key] = err
+	err.key = key
+	return nil
+}
+
+// MustRegister is like Register but panics if key is already
+// registered to a different error.
+func MustRegister(key string, err *ErrorEx) {
+	if regerr := Register(key, err); regerr != nil {
+		panic(regerr)
+	}
+}
+
+// Lookup returns the sentinel registered under key, and whether one
+// was found.
+func Lookup(key string) (err *ErrorEx, ok bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	err, ok = registry[key]
+	return
+}
@@ -0,0 +1,61 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package errorex
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestRegisterLookup(t *testing.T) {
+	sentinel := New("not found")
+	if err := Register("test:not-found", sentinel); err != nil {
+		t.Fatal(err)
+	}
+
+	found, ok := Lookup("test:not-found")
+	if !ok || found != sentinel {
+		t.Fatal("expected Lookup to return the registered sentinel")
+	}
+
+	if err := Register("test:not-found", sentinel); err != nil {
+		t.Fatal("re-registering the same error under the same key should not fail")
+	}
+
+	if err := Register("test:not-found", New("different")); err == nil {
+		t.Fatal("expected a collision error")
+	}
+}
+
+func TestMustRegisterPanics(t *testing.T) {
+	MustRegister("test:must-register", New("sentinel"))
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected MustRegister to panic on collision")
+		}
+	}()
+	MustRegister("test:must-register", New("other"))
+}
+
+func TestRegisteredSentinelSurvivesJSON(t *testing.T) {
+	sentinel := New("not found")
+	MustRegister("test:survives-json", sentinel)
+
+	wrapped := sentinel.Wrap("query failed")
+	b, err := json.Marshal(wrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded ErrorEx
+	if err = json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(&decoded, sentinel) {
+		t.Fatal("expected errors.Is to resolve the registered sentinel after round-trip")
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package errorex
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+)
+
+// maxstackdepth is the maximum number of program counters captured
+// for a single error.
+const maxstackdepth = 32
+
+// captureStacks controls whether New, NewFormat and the Wrap* family
+// of constructors record the call site's program counters.
+var captureStacks = true
+
+// CaptureStacks enables or disables stack trace capture for errors
+// constructed after the call. It is enabled by default; callers on hot
+// paths that construct many errors may want to turn it off to avoid
+// the cost of runtime.Callers.
+func CaptureStacks(enable bool) {
+	captureStacks = enable
+}
+
+// callers captures the program counters of the current goroutine's
+// stack and returns nil if stack capture is disabled. skip is passed to
+// runtime.Callers unchanged: 0 is runtime.Callers itself, 1 is callers
+// and 2 is the constructor calling callers, so 3 starts the trace at
+// that constructor's caller.
+func callers(skip int) []uintptr {
+	if !captureStacks {
+		return nil
+	}
+	var pcs [maxstackdepth]uintptr
+	n := runtime.Callers(skip, pcs[:])
+	return pcs[:n]
+}
+
+// StackTrace returns the call stack captured when this error was
+// constructed, or nil if capture was disabled or this error predates
+// the feature.
+func (ee *ErrorEx) StackTrace() []runtime.Frame {
+	if len(ee.stack) == 0 {
+		return nil
+	}
+	frames := runtime.CallersFrames(ee.stack)
+	result := make([]runtime.Frame, 0, len(ee.stack))
+	for {
+		frame, more := frames.Next()
+		result = append(result, frame)
+		if !more {
+			break
+		}
+	}
+	return result
+}
+
+// Format implements fmt.Formatter.
+//
+// Verbs 's' and 'v' print the same message as Error(). Verb '+v' renders
+// through the same Formatter as Error() (see activeFormatter): if that
+// is a VerboseFormatter, which already interleaves stack frames into its
+// output, that output is printed as-is. For any other formatter - e.g.
+// the default CompactFormatter - the Error() message is followed by a
+// file:line entry for each stack frame along the wrap chain, starting at
+// this error, deepest call first. Frames of a wrapped error that were
+// already printed for a derived error are skipped, similar to how
+// pkg/errors distinguishes a
+// fundamental error from one that merely adds a stack-less wrap.
+func (ee *ErrorEx) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			io.WriteString(s, ee.Error())
+			if _, verbose := ee.activeFormatter().(VerboseFormatter); verbose {
+				return
+			}
+			seen := make(map[runtime.Frame]bool)
+			var walk func(err error)
+			walk = func(err error) {
+				eex, ok := err.(*ErrorEx)
+				if !ok {
+					return
+				}
+				for _, frame := range eex.StackTrace() {
+					if seen[frame] {
+						continue
+					}
+					seen[frame] = true
+					fmt.Fprintf(s, "\n\t%s\n\t\t%s:%d", frame.Function, frame.File, frame.Line)
+				}
+				if eex.err != nil {
+					walk(eex.err)
+				}
+			}
+			walk(ee)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, ee.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", ee.Error())
+	}
+}
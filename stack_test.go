@@ -0,0 +1,63 @@
+// Copyright 2020 Vedran Vuk. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package errorex
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStackTrace(t *testing.T) {
+	err := New("base")
+	frames := err.StackTrace()
+	if len(frames) == 0 {
+		t.Fatal("expected captured stack frames")
+	}
+	found := false
+	for _, frame := range frames {
+		if strings.Contains(frame.Function, "TestStackTrace") {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Fatal("expected a frame referencing the test function")
+	}
+}
+
+func TestCaptureStacksDisabled(t *testing.T) {
+	CaptureStacks(false)
+	defer CaptureStacks(true)
+	if New("base").StackTrace() != nil {
+		t.Fatal("expected no stack trace when capture is disabled")
+	}
+}
+
+func callSite() *ErrorEx {
+	return NewFormat("fmt: %s").WrapArgs("arg")
+}
+
+func TestStackTraceSkipsComposedConstructors(t *testing.T) {
+	err := callSite()
+	frames := err.StackTrace()
+	if len(frames) == 0 {
+		t.Fatal("expected captured stack frames")
+	}
+	if !strings.Contains(frames[0].Function, "callSite") {
+		t.Fatalf("expected frame 0 to be the call site, got %s", frames[0].Function)
+	}
+}
+
+func TestFormatPlusV(t *testing.T) {
+	err := New("base").Wrap("sub")
+	s := fmt.Sprintf("%+v", err)
+	if !strings.HasPrefix(s, err.Error()) {
+		t.Fatal("expected verbose format to start with Error() message")
+	}
+	if !strings.Contains(s, "stack_test.go") {
+		t.Fatal("expected verbose format to include a source file entry")
+	}
+}
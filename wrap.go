@@ -4,11 +4,16 @@
 
 package errorex
 
-import "fmt"
-
 // Wrap wraps an error with a message.
 // If err is nil returns nil.
 // If message is empty error is not wrapped.
+//
+// The result is an *ErrorEx rather than a plain fmt.Errorf chain, so it
+// renders through the same Formatter as New/WrapFormat and is still
+// unwrapped by errors.Is/errors.As down to err. As with WithDataf, err's
+// own message is only surfaced by Error() when err is itself an
+// *ErrorEx, via the wrap chain; txt here is just this node's own
+// message, never a pre-baked concatenation with err.Error().
 func Wrap(err error, message string) error {
 	if err == nil {
 		return nil
@@ -16,13 +21,18 @@ func Wrap(err error, message string) error {
 	if message == "" {
 		return err
 	}
-	return fmt.Errorf("%w: %s", err, message)
+	return &ErrorEx{err: err, txt: message, stack: callers(3)}
 }
 
 // WrapCause wraps err with a message and appends the cause.
 // If err is empty returns nil.
 // If cause is nil, returns err wrapped with message.
 // If message is empty err is not wrapped.
+//
+// As with Wrap, txt is only ever this node's own message; err's message
+// surfaces through the wrap chain and cause is kept as a proper Cause
+// rather than pre-baked text, so both render the same way regardless of
+// whether the error came from WrapCause or ee.WrapCause.
 func WrapCause(err, cause error, message string) error {
 	if err == nil {
 		return nil
@@ -31,10 +41,10 @@ func WrapCause(err, cause error, message string) error {
 		if message == "" {
 			return err
 		}
-		return Wrap(err, message)
+		return &ErrorEx{err: err, txt: message, stack: callers(3)}
 	}
 	if message == "" {
-		return fmt.Errorf("%w: %v", err, cause)
+		return &ErrorEx{err: err, cause: cause, stack: callers(3)}
 	}
-	return fmt.Errorf("%w: %s: %v", err, message, cause)
-}
\ No newline at end of file
+	return &ErrorEx{err: err, cause: cause, txt: message, stack: callers(3)}
+}
@@ -6,6 +6,7 @@ package errorex
 
 import (
 	"errors"
+	"strings"
 	"testing"
 )
 
@@ -18,22 +19,47 @@ func TestUtilWrap(t *testing.T) {
 	if Wrap(ErrTest, "").Error() != "test" {
 		t.Fatal("TestUtilWrap failed")
 	}
-	if Wrap(ErrTest, "message").Error() != "test: message" {
+	// ErrTest is not an *ErrorEx, so - like WithDataf - its own message
+	// is not surfaced; only this node's own message is.
+	if Wrap(ErrTest, "message").Error() != "message" {
 		t.Fatal("TestUtilWrap failed")
 	}
 }
 
+func TestUtilWrapErrorEx(t *testing.T) {
+	// err is an *ErrorEx here, so its message is picked up via the
+	// normal wrap chain and must not be duplicated into the new node's
+	// text (see chunk0-6 fix).
+	if got := Wrap(New("base"), "msg").Error(); got != "base: msg" {
+		t.Fatalf("got %q, want %q", got, "base: msg")
+	}
+}
+
 func TestUtilWrapCause(t *testing.T) {
 	if WrapCause(ErrTest, nil, "").Error() != "test" {
 		t.Fatal("TestUtilWrapCause failed")
 	}
-	if WrapCause(ErrTest, ErrCause, "").Error() != "test: cause" {
+	if WrapCause(ErrTest, ErrCause, "").Error() != "test < cause" {
 		t.Fatal("TestUtilWrapCause failed")
 	}
-	if WrapCause(ErrTest, nil, "message").Error() != "test: message" {
+	if WrapCause(ErrTest, nil, "message").Error() != "message" {
 		t.Fatal("TestUtilWrapCause failed")
 	}
-	if WrapCause(ErrTest, ErrCause, "message").Error() != "test: cause: message" {
+	if WrapCause(ErrTest, ErrCause, "message").Error() != "message < cause" {
 		t.Fatal("TestUtilWrapCause failed")
 	}
 }
+
+func wrapCauseCallSite(err error) *ErrorEx {
+	return WrapCause(err, nil, "msg").(*ErrorEx)
+}
+
+func TestWrapCauseStackSkipsDelegation(t *testing.T) {
+	frames := wrapCauseCallSite(ErrTest).StackTrace()
+	if len(frames) == 0 {
+		t.Fatal("expected captured stack frames")
+	}
+	if !strings.Contains(frames[0].Function, "wrapCauseCallSite") {
+		t.Fatalf("expected frame 0 to be the call site, got %s", frames[0].Function)
+	}
+}